@@ -4,9 +4,11 @@ package collector
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/lognarrator/client/internal/config"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/processor"
@@ -16,27 +18,41 @@ import (
 
 // Collector represents the log collection pipeline
 type Collector struct {
-	cfg            config.CollectorConfig
-	cloudExporter  consumer.Logs
-	receivers      map[string]component.Component
-	processors     map[string]component.Component
-	exporters      map[string]component.Component
-	logger         *zap.SugaredLogger
+	cfg           config.CollectorConfig
+	cloudExporter exporter.Logs
+
+	receiverFactories  map[string]receiver.Factory
+	processorFactories map[string]processor.Factory
+	exporterFactories  map[string]exporter.Factory
+
+	receivers  map[string]component.Component
+	processors map[string]component.Component
+	exporters  map[string]component.Component
+
+	buildInfo component.BuildInfo
+	logger    *zap.SugaredLogger
 }
 
 // NewCollector creates a new collector instance
 func NewCollector(
 	cfg config.CollectorConfig,
-	cloudExporter consumer.Logs,
+	cloudExporter exporter.Logs,
 	logger *zap.SugaredLogger,
 ) (*Collector, error) {
 	return &Collector{
-		cfg:           cfg,
-		cloudExporter: cloudExporter,
-		receivers:     make(map[string]component.Component),
-		processors:    make(map[string]component.Component),
-		exporters:     make(map[string]component.Component),
-		logger:        logger,
+		cfg:                cfg,
+		cloudExporter:      cloudExporter,
+		receiverFactories:  defaultReceiverFactories(),
+		processorFactories: defaultProcessorFactories(),
+		exporterFactories:  defaultExporterFactories(cloudExporter),
+		receivers:          make(map[string]component.Component),
+		processors:         make(map[string]component.Component),
+		exporters:          make(map[string]component.Component),
+		buildInfo: component.BuildInfo{
+			Command:     "lognarrator-collector",
+			Description: "LogNarrator log collector",
+		},
+		logger: logger,
 	}, nil
 }
 
@@ -44,12 +60,31 @@ func NewCollector(
 func (c *Collector) Start(ctx context.Context) error {
 	c.logger.Info("Initializing collection pipeline")
 
-	// TODO: Initialize receivers, processors, and exporters from config
-	// This would involve setting up the OpenTelemetry collector components
+	if err := c.createPipelines(ctx); err != nil {
+		return fmt.Errorf("failed to create pipelines: %w", err)
+	}
 
-	// For now, just log that we're starting without actual implementation
-	c.logger.Info("Collection pipeline started (stub implementation)")
+	// Start bottom-up: exporters before processors before receivers, so
+	// nothing upstream can produce data before whatever it feeds is ready
+	// to accept it.
+	host := &noopHost{logger: c.logger}
+	for name, exp := range c.exporters {
+		if err := exp.Start(ctx, host); err != nil {
+			return fmt.Errorf("failed to start exporter %q: %w", name, err)
+		}
+	}
+	for name, proc := range c.processors {
+		if err := proc.Start(ctx, host); err != nil {
+			return fmt.Errorf("failed to start processor %q: %w", name, err)
+		}
+	}
+	for name, rcvr := range c.receivers {
+		if err := rcvr.Start(ctx, host); err != nil {
+			return fmt.Errorf("failed to start receiver %q: %w", name, err)
+		}
+	}
 
+	c.logger.Info("Collection pipeline started")
 	return nil
 }
 
@@ -57,41 +92,257 @@ func (c *Collector) Start(ctx context.Context) error {
 func (c *Collector) Shutdown(ctx context.Context) error {
 	c.logger.Info("Shutting down collection pipeline")
 
-	// Shut down components in reverse order: exporters, processors, receivers
-	for name, exporter := range c.exporters {
-		c.logger.Debugf("Shutting down exporter: %s", name)
-		if err := exporter.Shutdown(ctx); err != nil {
-			c.logger.Warnf("Error shutting down exporter %s: %v", name, err)
+	// Shut down top-down, the reverse of Start: receivers first so intake
+	// stops immediately, then processors and exporters so anything already
+	// in flight still has somewhere to drain to.
+	for name, rcvr := range c.receivers {
+		c.logger.Debugf("Shutting down receiver: %s", name)
+		if err := rcvr.Shutdown(ctx); err != nil {
+			c.logger.Warnf("Error shutting down receiver %s: %v", name, err)
 		}
 	}
 
-	for name, processor := range c.processors {
+	for name, proc := range c.processors {
 		c.logger.Debugf("Shutting down processor: %s", name)
-		if err := processor.Shutdown(ctx); err != nil {
+		if err := proc.Shutdown(ctx); err != nil {
 			c.logger.Warnf("Error shutting down processor %s: %v", name, err)
 		}
 	}
 
-	for name, rcvr := range c.receivers {
-		c.logger.Debugf("Shutting down receiver: %s", name)
-		if err := rcvr.Shutdown(ctx); err != nil {
-			c.logger.Warnf("Error shutting down receiver %s: %v", name, err)
+	for name, exp := range c.exporters {
+		c.logger.Debugf("Shutting down exporter: %s", name)
+		if err := exp.Shutdown(ctx); err != nil {
+			c.logger.Warnf("Error shutting down exporter %s: %v", name, err)
 		}
 	}
 
 	return nil
 }
 
-// createPipelines sets up the processing pipelines from the configuration
+// createPipelines builds every configured pipeline's receivers, processors
+// and exporters from CollectorConfig, wiring each one's next consumer to
+// the next stage, and records the resulting components in c.receivers,
+// c.processors and c.exporters so Start/Shutdown can drive their lifecycle.
 func (c *Collector) createPipelines(ctx context.Context) error {
 	c.logger.Debug("Creating processing pipelines")
 
-	// TODO: Implement the actual pipeline creation
-	// This would involve:
-	// 1. Create receivers based on config
-	// 2. Create processors based on config
-	// 3. Create exporters based on config
-	// 4. Connect components according to pipeline definitions
+	if len(c.cfg.Pipelines) == 0 {
+		return fmt.Errorf("no pipelines configured")
+	}
+
+	for pipelineID, pipeline := range c.cfg.Pipelines {
+		if dataType(pipelineID) != "logs" {
+			return fmt.Errorf("pipeline %q: only logs pipelines are supported", pipelineID)
+		}
+		if err := c.createLogsPipeline(ctx, pipelineID, pipeline); err != nil {
+			return fmt.Errorf("pipeline %q: %w", pipelineID, err)
+		}
+	}
+
+	return nil
+}
+
+// createLogsPipeline builds a single logs pipeline: the exporters it fans
+// out to, then its processors chained back-to-front onto that fanout, then
+// its receivers feeding the head of the chain.
+func (c *Collector) createLogsPipeline(ctx context.Context, pipelineID string, pipeline config.Pipeline) error {
+	if len(pipeline.Receivers) == 0 {
+		return fmt.Errorf("no receivers configured")
+	}
+	if len(pipeline.Exporters) == 0 {
+		return fmt.Errorf("no exporters configured")
+	}
+
+	exporterConsumers := make([]consumer.Logs, 0, len(pipeline.Exporters))
+	for _, id := range pipeline.Exporters {
+		logsExporter, err := c.getOrCreateExporter(ctx, id)
+		if err != nil {
+			return fmt.Errorf("exporter %q: %w", id, err)
+		}
+		exporterConsumers = append(exporterConsumers, logsExporter)
+	}
+
+	next, err := fanoutLogs(exporterConsumers...)
+	if err != nil {
+		return fmt.Errorf("failed to fan out to exporters: %w", err)
+	}
+
+	for i := len(pipeline.Processors) - 1; i >= 0; i-- {
+		id := pipeline.Processors[i]
+		proc, err := c.createProcessor(ctx, id, next)
+		if err != nil {
+			return fmt.Errorf("processor %q: %w", id, err)
+		}
+		c.processors[pipelineID+"/"+id] = proc
+		next = proc
+	}
+
+	for _, id := range pipeline.Receivers {
+		rcvr, err := c.createReceiver(ctx, id, next)
+		if err != nil {
+			return fmt.Errorf("receiver %q: %w", id, err)
+		}
+		c.receivers[pipelineID+"/"+id] = rcvr
+	}
+
+	return nil
+}
+
+// getOrCreateExporter returns the exporter named id, building and caching
+// it the first time it's referenced. Exporters are shared across pipelines,
+// unlike receivers and processors, since they have no downstream consumer
+// of their own to disambiguate on.
+func (c *Collector) getOrCreateExporter(ctx context.Context, id string) (exporter.Logs, error) {
+	if comp, ok := c.exporters[id]; ok {
+		return comp.(exporter.Logs), nil
+	}
+
+	factory, ok := c.exporterFactories[componentType(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown exporter type %q", componentType(id))
+	}
+
+	exp, err := factory.CreateLogsExporter(ctx, exporter.CreateSettings{
+		ID:                component.NewIDWithName(component.Type(componentType(id)), componentName(id)),
+		TelemetrySettings: component.TelemetrySettings{Logger: c.logger.Desugar()},
+		BuildInfo:         c.buildInfo,
+	}, factory.CreateDefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	c.exporters[id] = exp
+	return exp, nil
+}
+
+// createProcessor builds the processor named id from CollectorConfig.Processors,
+// wired to deliver to next.
+func (c *Collector) createProcessor(ctx context.Context, id string, next consumer.Logs) (processor.Logs, error) {
+	factory, ok := c.processorFactories[componentType(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown processor type %q", componentType(id))
+	}
+
+	cfg := factory.CreateDefaultConfig()
+	if err := decodeComponentConfig(c.cfg.Processors[id], cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	return factory.CreateLogsProcessor(ctx, processor.CreateSettings{
+		ID:                component.NewIDWithName(component.Type(componentType(id)), componentName(id)),
+		TelemetrySettings: component.TelemetrySettings{Logger: c.logger.Desugar()},
+		BuildInfo:         c.buildInfo,
+	}, cfg, next)
+}
+
+// createReceiver builds the receiver named id from CollectorConfig.Receivers,
+// wired to deliver to next.
+func (c *Collector) createReceiver(ctx context.Context, id string, next consumer.Logs) (component.Component, error) {
+	factory, ok := c.receiverFactories[componentType(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown receiver type %q", componentType(id))
+	}
+
+	cfg := factory.CreateDefaultConfig()
+	if err := decodeComponentConfig(c.cfg.Receivers[id], cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	return factory.CreateLogsReceiver(ctx, receiver.CreateSettings{
+		ID:                component.NewIDWithName(component.Type(componentType(id)), componentName(id)),
+		TelemetrySettings: component.TelemetrySettings{Logger: c.logger.Desugar()},
+		BuildInfo:         c.buildInfo,
+	}, cfg, next)
+}
+
+// componentType returns the type portion of a "type" or "type/name"
+// component ID, e.g. both "filelog" and "filelog/app" return "filelog".
+func componentType(id string) string {
+	if i := strings.IndexByte(id, '/'); i >= 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// componentName returns the name portion of a "type/name" component ID,
+// or "" if it has no name.
+func componentName(id string) string {
+	if i := strings.IndexByte(id, '/'); i >= 0 {
+		return id[i+1:]
+	}
+	return ""
+}
+
+// dataType returns the data-type portion of a pipeline ID, the same way
+// componentType does for a component ID ("logs/access" -> "logs").
+func dataType(pipelineID string) string {
+	return componentType(pipelineID)
+}
+
+// decodeComponentConfig decodes a receiver/processor config fragment -
+// loaded from YAML as interface{}, so nested maps come back as
+// map[interface{}]interface{} - into a factory's typed default config.
+func decodeComponentConfig(raw interface{}, out component.Config) error {
+	if raw == nil {
+		return nil
+	}
+
+	normalized, ok := normalizeYAML(raw).(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a mapping, got %T", raw)
+	}
+
+	return confmap.NewFromStringMap(normalized).Unmarshal(out)
+}
+
+// normalizeYAML recursively converts the map[interface{}]interface{} nodes
+// produced by gopkg.in/yaml.v2 into map[string]interface{}, which is what
+// confmap (and everything built on mapstructure) expects.
+func normalizeYAML(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			m[fmt.Sprintf("%v", k)] = normalizeYAML(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			m[k] = normalizeYAML(e)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, e := range val {
+			s[i] = normalizeYAML(e)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// noopHost is the minimal component.Host the collector gives to components
+// it starts. It doesn't support GetFactory-based dynamic component creation;
+// none of the receivers, processors or exporters this collector wires up
+// need it.
+type noopHost struct {
+	logger *zap.SugaredLogger
+}
+
+func (h *noopHost) ReportFatalError(err error) {
+	h.logger.Errorf("component reported fatal error: %v", err)
+}
+
+func (h *noopHost) GetFactory(kind component.Kind, componentType component.Type) component.Factory {
+	return nil
+}
+
+func (h *noopHost) GetExtensions() map[component.ID]component.Component {
+	return nil
+}
 
+func (h *noopHost) GetExporters() map[component.DataType]map[component.ID]component.Component {
 	return nil
 }
@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"context"
+
+	filelogreceiver "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/filelogreceiver"
+	syslogreceiver "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/syslogreceiver"
+
+	attributesprocessor "github.com/open-telemetry/opentelemetry-collector-contrib/processor/attributesprocessor"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.uber.org/multierr"
+)
+
+// cloudExporterType is the component type under which the in-tree
+// CloudExporter is registered in the exporter factory registry. Pipelines
+// reference it by this name (or "cloudexporter/<name>") in their
+// "exporters" list.
+const cloudExporterType = "cloudexporter"
+
+// defaultReceiverFactories returns the receiver factories this collector
+// knows how to build components for, keyed by component type.
+func defaultReceiverFactories() map[string]receiver.Factory {
+	return indexReceiverFactories(
+		filelogreceiver.NewFactory(),
+		otlpreceiver.NewFactory(),
+		syslogreceiver.NewFactory(),
+	)
+}
+
+// defaultProcessorFactories returns the processor factories this collector
+// knows how to build components for, keyed by component type.
+func defaultProcessorFactories() map[string]processor.Factory {
+	return indexProcessorFactories(
+		batchprocessor.NewFactory(),
+		memorylimiterprocessor.NewFactory(),
+		attributesprocessor.NewFactory(),
+	)
+}
+
+// defaultExporterFactories returns the exporter factories this collector
+// knows how to build components for, keyed by component type. cloudExporter
+// is the already-constructed, already-configured CloudExporter instance;
+// the wrapping factory hands it back verbatim rather than building a new
+// one from a pipeline's "exporters" config, since this collector has only
+// ever one exporter to send to.
+func defaultExporterFactories(cloudExporter exporter.Logs) map[string]exporter.Factory {
+	return indexExporterFactories(newCloudExporterFactory(cloudExporter))
+}
+
+func indexReceiverFactories(factories ...receiver.Factory) map[string]receiver.Factory {
+	m := make(map[string]receiver.Factory, len(factories))
+	for _, f := range factories {
+		m[string(f.Type())] = f
+	}
+	return m
+}
+
+func indexProcessorFactories(factories ...processor.Factory) map[string]processor.Factory {
+	m := make(map[string]processor.Factory, len(factories))
+	for _, f := range factories {
+		m[string(f.Type())] = f
+	}
+	return m
+}
+
+func indexExporterFactories(factories ...exporter.Factory) map[string]exporter.Factory {
+	m := make(map[string]exporter.Factory, len(factories))
+	for _, f := range factories {
+		m[string(f.Type())] = f
+	}
+	return m
+}
+
+// newCloudExporterFactory wraps an already-built CloudExporter so it can sit
+// in the same factory registry as the other exporters. Its CreateDefaultConfig
+// returns an empty marker config: CloudExporter is configured once, from
+// ExporterConfig, when it's constructed in cmd/collector, not per-pipeline.
+func newCloudExporterFactory(cloudExporter exporter.Logs) exporter.Factory {
+	return exporter.NewFactory(
+		cloudExporterType,
+		func() component.Config { return &struct{}{} },
+		exporter.WithLogs(
+			func(context.Context, exporter.CreateSettings, component.Config) (exporter.Logs, error) {
+				return cloudExporter, nil
+			},
+			component.StabilityLevelStable,
+		),
+	)
+}
+
+// fanoutLogs returns a consumer.Logs that forwards every call to each of
+// consumers in turn, combining any errors. It is a stand-in for the
+// collector's internal fanoutconsumer package, which is not importable
+// outside go.opentelemetry.io/collector itself.
+func fanoutLogs(consumers ...consumer.Logs) (consumer.Logs, error) {
+	if len(consumers) == 1 {
+		return consumers[0], nil
+	}
+	return consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		var errs error
+		for _, c := range consumers {
+			if err := c.ConsumeLogs(ctx, ld); err != nil {
+				errs = multierr.Append(errs, err)
+			}
+		}
+		return errs
+	})
+}
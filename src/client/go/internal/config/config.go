@@ -52,8 +52,67 @@ type ExporterConfig struct {
 	BatchSize      int    `yaml:"batchSize"`
 	MaxQueueSize   int    `yaml:"maxQueueSize"`
 	TLSCertPath    string `yaml:"tlsCertPath"`
-	TLSVerify      bool   `yaml:"tlsVerify"`
+	// TLSKeyPath is the private key matching the certificate at
+	// TLSCertPath, required for mTLS. The two must be set together.
+	TLSKeyPath string `yaml:"tlsKeyPath"`
+	// TLSCAPath, if set, loads a PEM CA bundle used instead of the system
+	// trust store to verify the server certificate.
+	TLSCAPath string `yaml:"tlsCaPath"`
+	// TLSVerify controls certificate verification and defaults to true.
+	// It's a pointer so applyDefaults can tell "unset" apart from an
+	// explicit "tlsVerify: false".
+	TLSVerify *bool `yaml:"tlsVerify"`
+	// Proxy overrides the HTTPS_PROXY/NO_PROXY environment variables with an
+	// explicit proxy URL. Empty means fall back to the environment.
+	Proxy          string `yaml:"proxy"`
 	LocalCachePath string `yaml:"localCachePath"`
+	// Protocol selects the wire format used to export logs: "json" (the
+	// default, LogNarrator's own schema), "otlp-http-proto", or
+	// "otlp-http-json".
+	Protocol string `yaml:"protocol"`
+	// Compression selects the payload compression: "none" (default) or "gzip".
+	Compression string `yaml:"compression"`
+	// HTTPLogging configures structured logging of outbound export requests.
+	HTTPLogging HTTPLoggingConfig `yaml:"httpLogging"`
+	// Auth configures request signing for endpoints that require it (e.g.
+	// AWS SigV4 for OpenSearch, API Gateway, or Kinesis Firehose HTTP).
+	Auth AuthConfig `yaml:"auth"`
+}
+
+// AuthConfig selects and configures a RequestSigner for outbound export
+// requests.
+type AuthConfig struct {
+	// Type selects the signer: "none" (the default) or "sigv4".
+	Type string `yaml:"type"`
+	// Region is the AWS region to sign for (sigv4 only).
+	Region string `yaml:"region"`
+	// Service is the AWS service name to sign for, e.g. "es" for
+	// OpenSearch, "execute-api" for API Gateway, "firehose" (sigv4 only).
+	Service string `yaml:"service"`
+	// AccessKeyID and SecretAccessKey are static credentials. Leave both
+	// empty, and set UseIMDS, to source credentials from the EC2 instance
+	// metadata service instead.
+	AccessKeyID     string `yaml:"accessKeyId"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+	SessionToken    string `yaml:"sessionToken"`
+	// UseIMDS sources credentials from the EC2 instance metadata service
+	// (IMDSv2) instead of AccessKeyID/SecretAccessKey.
+	UseIMDS bool `yaml:"useImds"`
+}
+
+// HTTPLoggingConfig controls the structured request/response audit log
+// CloudExporter emits for every outbound export call.
+type HTTPLoggingConfig struct {
+	// Enabled turns on the per-request log entry.
+	Enabled bool `yaml:"enabled"`
+	// LogBodies additionally logs a truncated sample of the request body,
+	// when it isn't encrypted.
+	LogBodies bool `yaml:"logBodies"`
+	// MaxBodyBytes caps how much of the body sample LogBodies includes.
+	MaxBodyBytes int `yaml:"maxBodyBytes"`
+	// RedactHeaders lists request header names (case-insensitive) whose
+	// values are replaced with "[REDACTED]" in the log entry.
+	RedactHeaders []string `yaml:"redactHeaders"`
 }
 
 // LoadConfig loads configuration from a file
@@ -116,9 +175,25 @@ func applyDefaults(config *Config) {
 	if config.Exporter.MaxQueueSize == 0 {
 		config.Exporter.MaxQueueSize = 10000
 	}
-	if config.Exporter.TLSVerify == false {
+	if config.Exporter.TLSVerify == nil {
 		// Default to secure configuration
-		config.Exporter.TLSVerify = true
+		verify := true
+		config.Exporter.TLSVerify = &verify
+	}
+	if config.Exporter.Protocol == "" {
+		config.Exporter.Protocol = "json"
+	}
+	if config.Exporter.Compression == "" {
+		config.Exporter.Compression = "none"
+	}
+	if config.Exporter.HTTPLogging.MaxBodyBytes == 0 {
+		config.Exporter.HTTPLogging.MaxBodyBytes = 2048
+	}
+	if len(config.Exporter.HTTPLogging.RedactHeaders) == 0 {
+		config.Exporter.HTTPLogging.RedactHeaders = []string{"Authorization", "Cookie", "X-Api-Key"}
+	}
+	if config.Exporter.Auth.Type == "" {
+		config.Exporter.Auth.Type = "none"
 	}
 }
 
@@ -152,6 +227,42 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("exporter.endpoint is required")
 	}
 
+	validProtocols := []string{"json", "otlp-http-proto", "otlp-http-json"}
+	valid := false
+	for _, p := range validProtocols {
+		if strings.EqualFold(config.Exporter.Protocol, p) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("unsupported exporter protocol: %s", config.Exporter.Protocol)
+	}
+
+	if !strings.EqualFold(config.Exporter.Compression, "none") && !strings.EqualFold(config.Exporter.Compression, "gzip") {
+		return fmt.Errorf("unsupported exporter compression: %s", config.Exporter.Compression)
+	}
+
+	if (config.Exporter.TLSCertPath == "") != (config.Exporter.TLSKeyPath == "") {
+		return fmt.Errorf("exporter.tlsCertPath and exporter.tlsKeyPath must be set together")
+	}
+
+	switch {
+	case strings.EqualFold(config.Exporter.Auth.Type, "none"):
+	case strings.EqualFold(config.Exporter.Auth.Type, "sigv4"):
+		if config.Exporter.Auth.Region == "" {
+			return fmt.Errorf("exporter.auth.region is required when auth.type is sigv4")
+		}
+		if config.Exporter.Auth.Service == "" {
+			return fmt.Errorf("exporter.auth.service is required when auth.type is sigv4")
+		}
+		if !config.Exporter.Auth.UseIMDS && (config.Exporter.Auth.AccessKeyID == "" || config.Exporter.Auth.SecretAccessKey == "") {
+			return fmt.Errorf("exporter.auth requires accessKeyId/secretAccessKey or useImds when auth.type is sigv4")
+		}
+	default:
+		return fmt.Errorf("unsupported exporter auth type: %s", config.Exporter.Auth.Type)
+	}
+
 	// Validate collector config
 	if len(config.Collector.Pipelines) == 0 {
 		return fmt.Errorf("at least one collector pipeline must be defined")
@@ -2,21 +2,43 @@
 package encryption
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lognarrator/client/internal/config"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
+// masterKeySize is the size, in bytes, of the long-lived key loaded from
+// KeyPath and of the per-batch data encryption keys (DEKs) it wraps.
+const masterKeySize = 32
+
 // Encryptor encrypts log data before transmission
 type Encryptor struct {
-	cfg       config.EncryptionConfig
-	privateKey []byte
-	clientID   string
+	cfg config.EncryptionConfig
+
+	mu           sync.Mutex
+	masterKey    []byte
+	keyCreatedAt time.Time
+	// keyGeneration identifies the live master key: 0 before any rotation,
+	// incremented on every rotation, and restored on startup from the number
+	// of "<KeyPath>.N" archives already on disk. It
+	// doubles as the archive suffix ("<KeyPath>.N") of the key that was
+	// rotated out to produce the current generation.
+	keyGeneration int
+	clientID      string
 }
 
 // EncryptedData represents encrypted log data
@@ -33,6 +55,13 @@ type EncryptedData struct {
 	Nonce string `json:"nonce"`
 	// Data is the encrypted payload (base64 encoded)
 	Data string `json:"data"`
+	// WrappedKey is the per-batch data encryption key, wrapped with the
+	// long-lived master key, base64 encoded (nonce prefix + ciphertext).
+	WrappedKey string `json:"wrappedKey"`
+	// KeyGeneration identifies which master key generation wrapped
+	// WrappedKey, so a decryptor can tell the live key from a rotated-out
+	// one archived under "<KeyPath>.<KeyGeneration>".
+	KeyGeneration int `json:"keyGeneration"`
 	// Compressed indicates if the original data was compressed
 	Compressed bool `json:"compressed"`
 }
@@ -43,17 +72,57 @@ func NewEncryptor(cfg config.EncryptionConfig) (*Encryptor, error) {
 		return &Encryptor{cfg: cfg}, nil
 	}
 
-	// Load private key
-	privateKey, err := ioutil.ReadFile(cfg.KeyPath)
+	masterKey, createdAt, err := loadMasterKey(cfg.KeyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read private key: %w", err)
 	}
 
-	return &Encryptor{
-		cfg:       cfg,
-		privateKey: privateKey,
-		clientID:   cfg.ClientID,
-	}, nil
+	e := &Encryptor{
+		cfg:          cfg,
+		masterKey:    masterKey,
+		keyCreatedAt: createdAt,
+		clientID:     cfg.ClientID,
+	}
+	e.keyGeneration = e.nextArchiveGeneration() - 1
+
+	return e, nil
+}
+
+// loadMasterKey reads the master key from path, accepting either a raw
+// 32-byte key or a base64-encoded one, and returns it alongside the key's
+// creation time (the file's mtime).
+func loadMasterKey(path string) ([]byte, time.Time, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	key := decodeMasterKey(raw)
+	if len(key) != masterKeySize {
+		return nil, time.Time{}, fmt.Errorf("key at %s must decode to %d bytes, got %d", path, masterKeySize, len(key))
+	}
+
+	return key, info.ModTime(), nil
+}
+
+// decodeMasterKey interprets raw key file contents as either a raw
+// masterKeySize-byte key or a base64-encoded one.
+func decodeMasterKey(raw []byte) []byte {
+	if len(raw) == masterKeySize {
+		return raw
+	}
+
+	trimmed := strings.TrimSpace(string(raw))
+	if decoded, err := base64.StdEncoding.DecodeString(trimmed); err == nil {
+		return decoded
+	}
+
+	return raw
 }
 
 // Encrypt encrypts the provided data
@@ -62,32 +131,51 @@ func (e *Encryptor) Encrypt(data []byte) (*EncryptedData, error) {
 		return nil, errors.New("encryption is disabled")
 	}
 
-	// Create encrypted data structure
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.rotationDue() {
+		if err := e.rotateLocked(); err != nil {
+			return nil, fmt.Errorf("failed to rotate key: %w", err)
+		}
+	}
+
+	// TODO: Implement compression. Until it's implemented, Compressed is
+	// left at its zero value (false) rather than set from e.cfg.Compression,
+	// since the data below is never actually compressed.
+
 	result := &EncryptedData{
-		ClientID:   e.clientID,
-		Timestamp:  time.Now().Unix(),
-		Version:    1,
-		Algorithm:  e.cfg.Algorithm,
-		Compressed: e.cfg.Compression,
+		ClientID:      e.clientID,
+		Timestamp:     time.Now().Unix(),
+		Version:       1,
+		Algorithm:     e.cfg.Algorithm,
+		KeyGeneration: e.keyGeneration,
 	}
 
-	// Compress if enabled
-	if e.cfg.Compression {
-		// TODO: Implement compression
-		// For now, just use the original data
+	dek := make([]byte, masterKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("failed to generate data encryption key: %w", err)
 	}
 
-	// Generate nonce/IV
-	nonce := make([]byte, 24) // XChaCha20-Poly1305 nonce size
+	aad := headerAAD(result)
+
+	dataAEAD, err := newAEAD(result.Algorithm, dek)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, dataAEAD.NonceSize())
 	if _, err := rand.Read(nonce); err != nil {
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
-
 	result.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	result.Data = base64.StdEncoding.EncodeToString(dataAEAD.Seal(nil, nonce, data, aad))
 
-	// TODO: Implement actual encryption with libsodium
-	// For now, just encode the data in base64 as a placeholder
-	result.Data = base64.StdEncoding.EncodeToString(data)
+	wrapped, err := e.wrapKey(dek, result.Algorithm, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data encryption key: %w", err)
+	}
+	result.WrappedKey = base64.StdEncoding.EncodeToString(wrapped)
 
 	return result, nil
 }
@@ -98,13 +186,61 @@ func (e *Encryptor) Decrypt(encData *EncryptedData) ([]byte, error) {
 		return nil, errors.New("encryption is disabled")
 	}
 
-	// TODO: Implement actual decryption with libsodium
-	// For now, just decode the base64 data as a placeholder
-	data, err := base64.StdEncoding.DecodeString(encData.Data)
+	if encData.Version != 1 {
+		return nil, fmt.Errorf("unsupported encryption format version: %d", encData.Version)
+	}
+	if !strings.EqualFold(encData.Algorithm, e.cfg.Algorithm) {
+		return nil, fmt.Errorf("encrypted data algorithm %q does not match configured algorithm %q", encData.Algorithm, e.cfg.Algorithm)
+	}
+
+	e.mu.Lock()
+	masterKey, err := e.keyForGenerationLocked(encData.KeyGeneration)
+	e.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	aad := headerAAD(encData)
+
+	wrapped, err := base64.StdEncoding.DecodeString(encData.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode wrapped key: %w", err)
+	}
+
+	wrapAEAD, err := newAEAD(encData.Algorithm, masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := wrapAEAD.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, errors.New("wrapped key is shorter than the nonce")
+	}
+	dek, err := wrapAEAD.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data encryption key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(encData.Algorithm, dek)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(encData.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode nonce: %w", err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encData.Data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode data: %w", err)
 	}
 
+	data, err := dataAEAD.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+
 	// Decompress if necessary
 	if encData.Compressed {
 		// TODO: Implement decompression
@@ -113,3 +249,142 @@ func (e *Encryptor) Decrypt(encData *EncryptedData) ([]byte, error) {
 
 	return data, nil
 }
+
+// wrapKey wraps dek with the current master key under the given algorithm
+// and associated data, returning the nonce-prefixed ciphertext.
+func (e *Encryptor) wrapKey(dek []byte, algorithm string, aad []byte) ([]byte, error) {
+	wrapAEAD, err := newAEAD(algorithm, e.masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, wrapAEAD.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate wrap nonce: %w", err)
+	}
+
+	return wrapAEAD.Seal(nonce, nonce, dek, aad), nil
+}
+
+// headerAAD builds the associated data that authenticates the encrypted
+// header fields (ClientID, Timestamp, Version, Algorithm) without being
+// part of the ciphertext itself, so servers can detect tampering.
+func headerAAD(d *EncryptedData) []byte {
+	header := struct {
+		ClientID  string `json:"clientId"`
+		Timestamp int64  `json:"timestamp"`
+		Version   int    `json:"version"`
+		Algorithm string `json:"algorithm"`
+	}{d.ClientID, d.Timestamp, d.Version, d.Algorithm}
+
+	// Marshaling a fixed struct (rather than a map) keeps field order
+	// deterministic, which matters because the AAD must match byte-for-byte
+	// between Encrypt and Decrypt.
+	aad, _ := json.Marshal(header)
+	return aad
+}
+
+// newAEAD constructs the AEAD cipher for algorithm using key, which must be
+// masterKeySize bytes.
+func newAEAD(algorithm string, key []byte) (cipher.AEAD, error) {
+	switch {
+	case strings.EqualFold(algorithm, "XChaCha20-Poly1305"):
+		return chacha20poly1305.NewX(key)
+	case strings.EqualFold(algorithm, "AES-256-GCM"):
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+		}
+		return cipher.NewGCM(block)
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm: %s", algorithm)
+	}
+}
+
+// rotationDue reports whether the master key has been in use for longer
+// than the configured KeyRotation period. Callers must hold e.mu.
+func (e *Encryptor) rotationDue() bool {
+	if e.cfg.KeyRotation <= 0 {
+		return false
+	}
+	return time.Since(e.keyCreatedAt) >= time.Duration(e.cfg.KeyRotation)*24*time.Hour
+}
+
+// rotateLocked generates a new master key, archives the previous one
+// alongside KeyPath so the cloud side can still decrypt batches wrapped
+// with it (keyForGenerationLocked resolves EncryptedData.KeyGeneration to
+// the right key), and installs the new key. Callers must hold e.mu.
+//
+// This does not re-encrypt batches already sitting in the exporter's send
+// queue: those are opaque, already-serialized bytes by the time they reach
+// the queue, which has no notion of encryption or keys, and the wrapped
+// DEK recorded on each one (plus KeyGeneration) is what lets the cloud
+// side decrypt them later, not re-wrapping on our end.
+func (e *Encryptor) rotateLocked() error {
+	newKey := make([]byte, masterKeySize)
+	if _, err := rand.Read(newKey); err != nil {
+		return fmt.Errorf("failed to generate new master key: %w", err)
+	}
+
+	archiveGen := e.nextArchiveGeneration()
+	archivePath := e.cfg.KeyPath + "." + strconv.Itoa(archiveGen)
+	if err := copyFile(e.cfg.KeyPath, archivePath); err != nil {
+		return fmt.Errorf("failed to archive previous key: %w", err)
+	}
+
+	encoded := []byte(base64.StdEncoding.EncodeToString(newKey))
+	if err := ioutil.WriteFile(e.cfg.KeyPath, encoded, 0600); err != nil {
+		return fmt.Errorf("failed to write rotated master key: %w", err)
+	}
+
+	e.masterKey = newKey
+	e.keyCreatedAt = time.Now()
+	// The key just archived as "<KeyPath>.<archiveGen>" was generation
+	// archiveGen-1; the new live key becomes generation archiveGen.
+	e.keyGeneration = archiveGen
+
+	return nil
+}
+
+// nextArchiveGeneration returns the next unused "<KeyPath>.N" generation
+// number, starting at 1.
+func (e *Encryptor) nextArchiveGeneration() int {
+	for n := 1; ; n++ {
+		if _, err := os.Stat(e.cfg.KeyPath + "." + strconv.Itoa(n)); os.IsNotExist(err) {
+			return n
+		}
+	}
+}
+
+// keyForGenerationLocked returns the master key for the given generation:
+// the live key if it matches e.keyGeneration, or the archived key loaded
+// from "<KeyPath>.<generation+1>" otherwise (the archive created when that
+// generation was rotated out). Callers must hold e.mu.
+func (e *Encryptor) keyForGenerationLocked(generation int) ([]byte, error) {
+	if generation == e.keyGeneration {
+		return e.masterKey, nil
+	}
+	if generation > e.keyGeneration {
+		return nil, fmt.Errorf("key generation %d is newer than the current key generation %d", generation, e.keyGeneration)
+	}
+
+	archivePath := e.cfg.KeyPath + "." + strconv.Itoa(generation+1)
+	key, _, err := loadMasterKey(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archived key for generation %d: %w", generation, err)
+	}
+	return key, nil
+}
+
+// copyFile copies the file at src to dst, creating dst's parent directory
+// if necessary.
+func copyFile(src, dst string) error {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, data, 0600)
+}
@@ -3,25 +3,42 @@ package exporter
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lognarrator/client/internal/config"
 	"github.com/lognarrator/client/internal/encryption"
+	"github.com/lognarrator/client/internal/exporter/queue"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.uber.org/zap"
 )
 
+// requestIDHeader carries the audit-log request ID to the server so its
+// logs can be correlated with ours.
+const requestIDHeader = "X-Request-ID"
+
 // CloudExporter exports logs to the LogNarrator cloud
 type CloudExporter struct {
 	cfg       config.ExporterConfig
 	encryptor *encryption.Encryptor
 	client    *http.Client
+	signer    RequestSigner
 	logger    *zap.SugaredLogger
+	queue     *queue.Queue
 }
 
 // NewCloudExporter creates a new cloud exporter
@@ -30,33 +47,55 @@ func NewCloudExporter(
 	encryptor *encryption.Encryptor,
 	logger *zap.SugaredLogger,
 ) (*CloudExporter, error) {
+	transport, err := buildTransport(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP transport: %w", err)
+	}
+
 	// Create HTTP client with appropriate timeouts
 	client := &http.Client{
-		Timeout: time.Duration(cfg.Timeout) * time.Second,
-		// TODO: Configure TLS settings
+		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+		Transport: transport,
+	}
+
+	signer, err := buildSigner(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure request signer: %w", err)
 	}
 
-	return &CloudExporter{
+	e := &CloudExporter{
 		cfg:       cfg,
 		encryptor: encryptor,
 		client:    client,
+		signer:    signer,
 		logger:    logger,
-	}, nil
+	}
+
+	q, err := queue.New(queue.Config{
+		Dir:           cfg.LocalCachePath,
+		MaxQueueSize:  cfg.MaxQueueSize,
+		RetryDelay:    time.Duration(cfg.RetryDelaySec) * time.Second,
+		RetryMaxCount: cfg.RetryMaxCount,
+	}, e.sendBatch, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize send queue: %w", err)
+	}
+	e.queue = q
+
+	return e, nil
 }
 
 // ConsumeLogs implements the OpenTelemetry logs consumer interface
 func (e *CloudExporter) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
 	e.logger.Debugf("Received %d log records for export", logs.LogRecordCount())
 
-	// Convert logs to JSON
-	logData, err := e.logsToJSON(logs)
+	logData, contentType, err := e.marshalLogs(logs)
 	if err != nil {
-		return fmt.Errorf("failed to convert logs to JSON: %w", err)
+		return fmt.Errorf("failed to marshal logs: %w", err)
 	}
 
 	// Encrypt the logs if encryption is enabled
 	var payload []byte
-	var contentType string
 
 	if e.encryptor != nil {
 		encData, err := e.encryptor.Encrypt(logData)
@@ -72,38 +111,272 @@ func (e *CloudExporter) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
 
 		contentType = "application/json+encrypted"
 	} else {
-		// Use the raw JSON data
 		payload = logData
-		contentType = "application/json"
 	}
 
-	// Send to the cloud endpoint
+	endpoint := e.cfg.Endpoint
+	if isOTLPProtocol(e.cfg.Protocol) {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/logs"
+	}
+
+	var contentEncoding string
+	if strings.EqualFold(e.cfg.Compression, "gzip") {
+		payload, err = gzipCompress(payload)
+		if err != nil {
+			return fmt.Errorf("failed to gzip payload: %w", err)
+		}
+		contentEncoding = "gzip"
+	}
+
+	if err := e.queue.Enqueue(queue.Batch{
+		Endpoint:        endpoint,
+		ContentType:     contentType,
+		ContentEncoding: contentEncoding,
+		Payload:         payload,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue logs: %w", err)
+	}
+
+	e.logger.Debugf("Enqueued %d log records for export", logs.LogRecordCount())
+	return nil
+}
+
+// sendBatch is the queue.SendFunc that actually delivers a batch over HTTP.
+// It classifies the response so the queue knows whether to retry: 4xx
+// responses other than 408/429 are permanent, everything else (including
+// network errors) is retried with backoff.
+func (e *CloudExporter) sendBatch(ctx context.Context, batch queue.Batch, attempt int) error {
+	requestID := uuid.NewString()
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		e.cfg.Endpoint,
-		bytes.NewBuffer(payload),
+		batch.Endpoint,
+		bytes.NewReader(batch.Payload),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return queue.Permanent(fmt.Errorf("failed to create request: %w", err))
 	}
 
-	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-Type", batch.ContentType)
 	req.Header.Set("User-Agent", "LogNarrator-Collector/0.1.0")
+	req.Header.Set(requestIDHeader, requestID)
+	if batch.ContentEncoding != "" {
+		req.Header.Set("Content-Encoding", batch.ContentEncoding)
+	}
+
+	if e.signer != nil {
+		if err := e.signer.Sign(req, batch.Payload); err != nil {
+			return queue.Permanent(fmt.Errorf("failed to sign request: %w", err))
+		}
+	}
 
-	// TODO: Add retry logic
+	start := time.Now()
 	resp, err := e.client.Do(req)
+	elapsed := time.Since(start)
 	if err != nil {
+		e.logHTTPRequest(batch, req.Header, requestID, attempt, elapsed, 0, nil, err)
 		return fmt.Errorf("failed to send logs: %w", err)
 	}
 	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+
+	e.logHTTPRequest(batch, req.Header, requestID, attempt, elapsed, resp.StatusCode, respBody, nil)
 
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("server returned error: %s", resp.Status)
+	if resp.StatusCode < 400 {
+		return nil
 	}
 
-	e.logger.Debugf("Successfully exported %d log records", logs.LogRecordCount())
-	return nil
+	sendErr := fmt.Errorf("server returned error: %s", resp.Status)
+	if resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return queue.Retryable(sendErr, retryAfter(resp))
+	}
+	return queue.Permanent(sendErr)
+}
+
+// logHTTPRequest emits the structured audit-log entry for one export call,
+// when ExporterConfig.HTTPLogging is enabled. respStatus is 0 and respBody
+// is nil if the request never got a response (sendErr is then the transport
+// error).
+func (e *CloudExporter) logHTTPRequest(batch queue.Batch, reqHeader http.Header, requestID string, attempt int, elapsed time.Duration, respStatus int, respBody []byte, sendErr error) {
+	cfg := e.cfg.HTTPLogging
+	if !cfg.Enabled {
+		return
+	}
+
+	fields := []interface{}{
+		"request_id", requestID,
+		"method", "POST",
+		"url", redactQuery(batch.Endpoint),
+		"headers", redactHeaders(reqHeader, cfg.RedactHeaders),
+		"attempt", attempt,
+		"request_bytes", len(batch.Payload),
+		"elapsed_ms", elapsed.Milliseconds(),
+	}
+	if sendErr != nil {
+		fields = append(fields, "error", sendErr.Error())
+	} else {
+		fields = append(fields, "status_code", respStatus, "response_bytes", len(respBody))
+	}
+
+	if cfg.LogBodies {
+		fields = append(fields, bodySampleField(batch, cfg.MaxBodyBytes)...)
+	}
+
+	if sendErr != nil || respStatus >= 400 {
+		e.logger.Warnw("cloud export request failed", fields...)
+		return
+	}
+	e.logger.Infow("cloud export request", fields...)
+}
+
+// redactQuery replaces a non-empty query string in rawURL with "REDACTED"
+// so logged URLs never leak query-parameter values (API keys, signed URL
+// params, etc.).
+func redactQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "REDACTED"
+	}
+	return u.String()
+}
+
+// redactHeaders returns header's values as a map, with any header named in
+// redact (case-insensitive) replaced by "[REDACTED]" so secrets like
+// Authorization or Cookie never reach the audit log.
+func redactHeaders(header http.Header, redact []string) map[string]string {
+	redactSet := make(map[string]struct{}, len(redact))
+	for _, name := range redact {
+		redactSet[strings.ToLower(name)] = struct{}{}
+	}
+
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if _, ok := redactSet[strings.ToLower(name)]; ok {
+			result[name] = "[REDACTED]"
+			continue
+		}
+		result[name] = strings.Join(values, ",")
+	}
+	return result
+}
+
+// bodySampleField returns the "body" log field for batch's payload: a
+// truncated, pretty-printed sample of the plaintext JSON, or, when the
+// payload is encrypted, just the EncryptedData envelope metadata (no
+// ciphertext or key material).
+func bodySampleField(batch queue.Batch, maxBytes int) []interface{} {
+	payload := batch.Payload
+	if batch.ContentEncoding == "gzip" {
+		decompressed, err := gzipDecompress(payload)
+		if err != nil {
+			return []interface{}{"body_error", fmt.Sprintf("failed to gunzip body for logging: %v", err)}
+		}
+		payload = decompressed
+	}
+
+	if batch.ContentType == "application/json+encrypted" {
+		var encData encryption.EncryptedData
+		if err := json.Unmarshal(payload, &encData); err != nil {
+			return []interface{}{"body_error", fmt.Sprintf("failed to parse encrypted envelope for logging: %v", err)}
+		}
+		nonce, err := base64.StdEncoding.DecodeString(encData.Nonce)
+		if err != nil {
+			return []interface{}{"body_error", fmt.Sprintf("failed to decode envelope nonce for logging: %v", err)}
+		}
+		return []interface{}{
+			"body_client_id", encData.ClientID,
+			"body_version", encData.Version,
+			"body_algorithm", encData.Algorithm,
+			"body_nonce_bytes", len(nonce),
+		}
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, payload, "", "  "); err != nil {
+		return []interface{}{"body_error", fmt.Sprintf("failed to pretty-print body for logging: %v", err)}
+	}
+
+	sample := pretty.Bytes()
+	truncated := false
+	if maxBytes > 0 && len(sample) > maxBytes {
+		sample = sample[:maxBytes]
+		truncated = true
+	}
+	return []interface{}{"body_sample", string(sample), "body_truncated", truncated}
+}
+
+// gzipDecompress reverses gzipCompress, used only to recover a readable
+// body sample for logging.
+func gzipDecompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(zr)
+}
+
+// retryAfter parses the Retry-After header as a number of seconds,
+// returning 0 (let the queue use its own backoff) if it's absent or
+// unparseable.
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// marshalLogs converts logs to the wire format selected by
+// ExporterConfig.Protocol and returns the payload along with the
+// Content-Type it must be sent with.
+func (e *CloudExporter) marshalLogs(logs plog.Logs) ([]byte, string, error) {
+	switch {
+	case strings.EqualFold(e.cfg.Protocol, "otlp-http-proto"):
+		payload, err := plogotlp.NewExportRequestFromLogs(logs).MarshalProto()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal OTLP protobuf: %w", err)
+		}
+		return payload, "application/x-protobuf", nil
+	case strings.EqualFold(e.cfg.Protocol, "otlp-http-json"):
+		payload, err := plogotlp.NewExportRequestFromLogs(logs).MarshalJSON()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal OTLP JSON: %w", err)
+		}
+		return payload, "application/json", nil
+	default:
+		payload, err := e.logsToJSON(logs)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to convert logs to JSON: %w", err)
+		}
+		return payload, "application/json", nil
+	}
+}
+
+// isOTLPProtocol reports whether protocol is one of the OTLP/HTTP variants.
+func isOTLPProtocol(protocol string) bool {
+	return strings.EqualFold(protocol, "otlp-http-proto") || strings.EqualFold(protocol, "otlp-http-json")
+}
+
+// gzipCompress compresses data using gzip.
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // Capabilities implements the OpenTelemetry consumer capabilities interface
@@ -112,15 +385,15 @@ func (e *CloudExporter) Capabilities() consumer.Capabilities {
 }
 
 // Start implements the component.Component interface
-func (e *CloudExporter) Start(ctx context.Context, host interface{}) error {
+func (e *CloudExporter) Start(ctx context.Context, host component.Host) error {
 	e.logger.Info("Starting LogNarrator cloud exporter")
-	return nil
+	return e.queue.Start(ctx)
 }
 
 // Shutdown implements the component.Component interface
 func (e *CloudExporter) Shutdown(ctx context.Context) error {
 	e.logger.Info("Shutting down LogNarrator cloud exporter")
-	return nil
+	return e.queue.Shutdown(ctx)
 }
 
 // logsToJSON converts OpenTelemetry logs to JSON format
@@ -147,18 +420,18 @@ func (e *CloudExporter) logsToJSON(logs plog.Logs) ([]byte, error) {
 		Records: make([]LogRecord, 0, logs.LogRecordCount()),
 	}
 
-	resourceMap := func(res plog.Resource) map[string]string {
+	resourceMap := func(res pcommon.Resource) map[string]string {
 		result := make(map[string]string)
-		res.Attributes().Range(func(k string, v plog.Value) bool {
+		res.Attributes().Range(func(k string, v pcommon.Value) bool {
 			result[k] = v.AsString()
 			return true
 		})
 		return result
 	}
 
-	attributeMap := func(attrs plog.Map) map[string]string {
+	attributeMap := func(attrs pcommon.Map) map[string]string {
 		result := make(map[string]string)
-		attrs.Range(func(k string, v plog.Value) bool {
+		attrs.Range(func(k string, v pcommon.Value) bool {
 			result[k] = v.AsString()
 			return true
 		})
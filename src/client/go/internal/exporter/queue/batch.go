@@ -0,0 +1,60 @@
+// Package queue implements a persistent, WAL-backed send queue for the
+// cloud exporter. Batches are durably appended to disk before being handed
+// to a pluggable sender, so a crash or restart never silently drops data
+// that was already accepted from the collection pipeline.
+package queue
+
+import (
+	"errors"
+	"time"
+)
+
+// Batch is a single outbound payload along with the HTTP metadata needed
+// to resend it.
+type Batch struct {
+	// Endpoint is the fully-resolved URL the batch must be POSTed to.
+	Endpoint string
+	// ContentType is the value of the Content-Type header.
+	ContentType string
+	// ContentEncoding is the value of the Content-Encoding header, or empty
+	// if the payload is not encoded.
+	ContentEncoding string
+	// Payload is the request body, already marshaled, encrypted and
+	// compressed as configured.
+	Payload []byte
+}
+
+// ErrQueueFull is returned by Enqueue once the on-disk queue holds
+// Config.MaxQueueSize undelivered batches or more. Callers should surface
+// this as backpressure rather than drop the batch themselves.
+var ErrQueueFull = errors.New("queue: on-disk queue is full")
+
+// PermanentError marks a send failure that must never be retried, e.g. a
+// 4xx response that isn't 408 or 429. The queue drops the batch.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent wraps err so the queue treats it as a permanent drop.
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// RetryableError marks a send failure that should be retried, optionally
+// after a server-specified delay (e.g. a Retry-After header).
+type RetryableError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string { return e.Err.Error() }
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// Retryable wraps err so the queue retries it with backoff, waiting at
+// least after before the next attempt.
+func Retryable(err error, after time.Duration) error {
+	return &RetryableError{Err: err, RetryAfter: after}
+}
@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const cursorFileName = "cursor.json"
+
+// loadCursor reads the last committed read position, or the zero position
+// (segment 0, offset 0) if no cursor has been written yet.
+func loadCursor(dir string) (position, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, cursorFileName))
+	if os.IsNotExist(err) {
+		return position{}, nil
+	}
+	if err != nil {
+		return position{}, fmt.Errorf("failed to read cursor: %w", err)
+	}
+
+	var pos position
+	if err := json.Unmarshal(data, &pos); err != nil {
+		return position{}, fmt.Errorf("failed to parse cursor: %w", err)
+	}
+	return pos, nil
+}
+
+// saveCursor durably persists pos as the last committed read position,
+// writing to a temp file and renaming over the old cursor so a crash
+// mid-write can never leave a corrupt cursor behind.
+func saveCursor(dir string, pos position) error {
+	data, err := json.Marshal(pos)
+	if err != nil {
+		return fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	tmpPath := filepath.Join(dir, cursorFileName+".tmp")
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cursor: %w", err)
+	}
+
+	f, err := os.OpenFile(tmpPath, os.O_RDWR, 0600)
+	if err == nil {
+		f.Sync()
+		f.Close()
+	}
+
+	return os.Rename(tmpPath, filepath.Join(dir, cursorFileName))
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"expvar"
+	"net/http"
+	"sync/atomic"
+)
+
+// metricsMap publishes queue counters under a single process-wide expvar
+// name. Individual counters are exposed as expvar.Func so they always
+// reflect the live atomic values; each NewMetrics call replaces the
+// counters published by the previous one.
+var metricsMap = expvar.NewMap("lognarrator_exporter_queue")
+
+// Metrics tracks the lifecycle of batches moving through a Queue.
+type Metrics struct {
+	Enqueued int64
+	Sent     int64
+	Dropped  int64
+	Retried  int64
+	Depth    int64
+}
+
+// NewMetrics creates a Metrics instance and publishes its counters on the
+// shared expvar map.
+func NewMetrics() *Metrics {
+	m := &Metrics{}
+	metricsMap.Set("enqueued", expvar.Func(func() interface{} { return atomic.LoadInt64(&m.Enqueued) }))
+	metricsMap.Set("sent", expvar.Func(func() interface{} { return atomic.LoadInt64(&m.Sent) }))
+	metricsMap.Set("dropped", expvar.Func(func() interface{} { return atomic.LoadInt64(&m.Dropped) }))
+	metricsMap.Set("retried", expvar.Func(func() interface{} { return atomic.LoadInt64(&m.Retried) }))
+	metricsMap.Set("queue_depth", expvar.Func(func() interface{} { return atomic.LoadInt64(&m.Depth) }))
+	return m
+}
+
+// Handler returns the standard expvar HTTP handler, which serves every
+// registered metric (including these queue counters) as JSON.
+func Handler() http.Handler {
+	return expvar.Handler()
+}
@@ -0,0 +1,472 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaults applied to any zero-valued Config field.
+const (
+	defaultWorkers         = 4
+	defaultMaxSegmentBytes = 16 * 1024 * 1024
+	defaultRetryDelay      = 10 * time.Second
+	defaultRetryMaxDelay   = 5 * time.Minute
+)
+
+// SendFunc delivers a single batch. attempt is the 1-indexed try number for
+// this batch. It should return a *PermanentError for failures that must
+// never be retried, a *RetryableError for failures that should be retried
+// (optionally after a server-specified delay), or a plain error for
+// anything else, which the queue retries with backoff.
+type SendFunc func(ctx context.Context, batch Batch, attempt int) error
+
+// Config configures a Queue.
+type Config struct {
+	// Dir is the directory the WAL segments and cursor are stored in
+	// (ExporterConfig.LocalCachePath).
+	Dir string
+	// MaxQueueSize caps the number of undelivered batches allowed on disk
+	// before Enqueue starts returning ErrQueueFull (ExporterConfig.MaxQueueSize).
+	MaxQueueSize int
+	// Workers is the size of the sender goroutine pool.
+	Workers int
+	// MaxSegmentBytes is the size a WAL segment grows to before rolling
+	// over to a new one.
+	MaxSegmentBytes int64
+	// RetryDelay is the base delay for the exponential backoff with full
+	// jitter applied between retries (ExporterConfig.RetryDelaySec).
+	RetryDelay time.Duration
+	// RetryMaxDelay caps the backoff delay.
+	RetryMaxDelay time.Duration
+	// RetryMaxCount is the number of retries attempted before a batch is
+	// dropped permanently. Zero means retry forever (ExporterConfig.RetryMaxCount).
+	RetryMaxCount int
+}
+
+// pendingEntry tracks a record that has been read off the WAL and handed to
+// a worker, but not yet committed to the cursor.
+type pendingEntry struct {
+	pos   position
+	next  position
+	batch Batch
+	done  bool
+}
+
+// Queue is a persistent, WAL-backed send queue. Enqueue durably appends
+// batches; a pool of workers drains them via SendFunc with retry and
+// backoff, and Start replays anything left over from a previous run.
+type Queue struct {
+	cfg     Config
+	wal     *wal
+	sender  SendFunc
+	logger  *zap.SugaredLogger
+	metrics *Metrics
+
+	mu        sync.Mutex
+	pending   []*pendingEntry
+	committed position
+
+	workCh chan *pendingEntry
+	doneCh chan *pendingEntry
+	notify chan struct{}
+	stop   chan struct{}
+
+	workersWG sync.WaitGroup
+	commitWG  sync.WaitGroup
+}
+
+// New creates a Queue backed by a WAL under cfg.Dir. Call Start to replay
+// any backlog and begin draining it.
+func New(cfg Config, sender SendFunc, logger *zap.SugaredLogger) (*Queue, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.MaxSegmentBytes <= 0 {
+		cfg.MaxSegmentBytes = defaultMaxSegmentBytes
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+	if cfg.RetryMaxDelay <= 0 {
+		cfg.RetryMaxDelay = defaultRetryMaxDelay
+	}
+
+	w, err := openWAL(cfg.Dir, cfg.MaxSegmentBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Queue{
+		cfg:     cfg,
+		wal:     w,
+		sender:  sender,
+		logger:  logger,
+		metrics: NewMetrics(),
+		workCh:  make(chan *pendingEntry, cfg.Workers),
+		doneCh:  make(chan *pendingEntry, cfg.Workers),
+		notify:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}, nil
+}
+
+// Metrics returns the queue's counters.
+func (q *Queue) Metrics() *Metrics {
+	return q.metrics
+}
+
+// Enqueue durably appends batch to the WAL and wakes the dispatcher. It
+// returns ErrQueueFull once the on-disk backlog reaches Config.MaxQueueSize.
+func (q *Queue) Enqueue(batch Batch) error {
+	if q.cfg.MaxQueueSize > 0 && atomic.LoadInt64(&q.metrics.Depth) >= int64(q.cfg.MaxQueueSize) {
+		return ErrQueueFull
+	}
+
+	rec := persistedRecord{
+		Endpoint:        batch.Endpoint,
+		ContentType:     batch.ContentType,
+		ContentEncoding: batch.ContentEncoding,
+		Payload:         batch.Payload,
+	}
+	if _, err := q.wal.append(rec); err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&q.metrics.Enqueued, 1)
+	atomic.AddInt64(&q.metrics.Depth, 1)
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start replays any backlog left over from a previous run (starting from
+// the last committed cursor) and launches the dispatcher, worker pool and
+// committer.
+func (q *Queue) Start(ctx context.Context) error {
+	cursor, err := loadCursor(q.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	if cursor.Segment == 0 {
+		cursor.Segment = 1
+	}
+	q.committed = cursor
+
+	depth, err := q.countPending(cursor)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt64(&q.metrics.Depth, depth)
+
+	q.commitWG.Add(1)
+	go q.commitLoop()
+
+	q.workersWG.Add(1 + q.cfg.Workers)
+	go q.dispatchLoop(cursor)
+	for i := 0; i < q.cfg.Workers; i++ {
+		go q.workerLoop(ctx)
+	}
+
+	return nil
+}
+
+// Shutdown stops the dispatcher and workers, drains in-flight completions
+// to the committer, and closes the WAL. Any backlog left undelivered stays
+// on disk for the next Start to replay.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	close(q.stop)
+	q.workersWG.Wait()
+	close(q.doneCh)
+	q.commitWG.Wait()
+	return q.wal.close()
+}
+
+// countPending counts the records remaining in the WAL from start onward,
+// used to seed the depth gauge on Start.
+func (q *Queue) countPending(start position) (int64, error) {
+	var count int64
+	seq, offset := start.Segment, start.Offset
+
+	for {
+		reader, err := openSegmentReader(q.wal.dir, seq, offset)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+
+		for {
+			_, next, err := reader.next()
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				reader.close()
+				return count, err
+			}
+			count++
+			offset = next
+		}
+		reader.close()
+
+		nextSeq, ok, err := q.wal.hasNewerSegment(seq)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			break
+		}
+		seq, offset = nextSeq, 0
+	}
+
+	return count, nil
+}
+
+// dispatchLoop reads records sequentially from the WAL starting at cursor
+// and hands them to the worker pool via workCh, in order.
+func (q *Queue) dispatchLoop(cursor position) {
+	defer q.workersWG.Done()
+	defer close(q.workCh)
+
+	seq, offset := cursor.Segment, cursor.Offset
+	var reader *segmentReader
+
+	openCurrent := func() error {
+		var err error
+		reader, err = openSegmentReader(q.wal.dir, seq, offset)
+		if os.IsNotExist(err) {
+			reader = nil
+			return nil
+		}
+		return err
+	}
+
+	if err := openCurrent(); err != nil {
+		q.logger.Errorf("queue dispatcher failed to open WAL segment %d: %v", seq, err)
+		return
+	}
+
+	for {
+		select {
+		case <-q.stop:
+			if reader != nil {
+				reader.close()
+			}
+			return
+		default:
+		}
+
+		if reader == nil {
+			select {
+			case <-q.notify:
+			case <-time.After(time.Second):
+			case <-q.stop:
+				return
+			}
+			if err := openCurrent(); err != nil {
+				q.logger.Errorf("queue dispatcher failed to open WAL segment %d: %v", seq, err)
+				return
+			}
+			continue
+		}
+
+		rec, next, err := reader.next()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if err == io.ErrUnexpectedEOF {
+				q.logger.Warnf("queue: skipping torn record at end of WAL segment %d", seq)
+			}
+			reader.close()
+			reader = nil
+
+			nextSeq, ok, hErr := q.wal.hasNewerSegment(seq)
+			if hErr != nil {
+				q.logger.Errorf("queue dispatcher failed to list WAL segments: %v", hErr)
+				return
+			}
+			if ok {
+				seq, offset = nextSeq, 0
+				if err := openCurrent(); err != nil {
+					q.logger.Errorf("queue dispatcher failed to open WAL segment %d: %v", seq, err)
+					return
+				}
+			}
+			continue
+		}
+		if err != nil {
+			q.logger.Errorf("queue dispatcher failed to read WAL segment %d: %v", seq, err)
+			reader.close()
+			return
+		}
+
+		entry := &pendingEntry{
+			pos:  position{Segment: seq, Offset: offset},
+			next: position{Segment: seq, Offset: next},
+			batch: Batch{
+				Endpoint:        rec.Endpoint,
+				ContentType:     rec.ContentType,
+				ContentEncoding: rec.ContentEncoding,
+				Payload:         rec.Payload,
+			},
+		}
+		offset = next
+
+		q.mu.Lock()
+		q.pending = append(q.pending, entry)
+		q.mu.Unlock()
+
+		select {
+		case q.workCh <- entry:
+		case <-q.stop:
+			reader.close()
+			return
+		}
+	}
+}
+
+// workerLoop sends batches handed to it by the dispatcher, with retry and
+// backoff, then reports completion to the committer.
+func (q *Queue) workerLoop(ctx context.Context) {
+	defer q.workersWG.Done()
+
+	for entry := range q.workCh {
+		if q.process(ctx, entry) {
+			q.doneCh <- entry
+		}
+	}
+}
+
+// process attempts to send entry's batch, retrying with backoff until it
+// succeeds, is permanently dropped, or the queue is shutting down. It
+// returns false if processing was interrupted by shutdown, in which case
+// the batch is left undelivered in the WAL for the next Start to replay.
+func (q *Queue) process(ctx context.Context, entry *pendingEntry) bool {
+	attempts := 0
+
+	for {
+		select {
+		case <-q.stop:
+			return false
+		case <-ctx.Done():
+			return false
+		default:
+		}
+
+		err := q.sender(ctx, entry.batch, attempts+1)
+		if err == nil {
+			atomic.AddInt64(&q.metrics.Sent, 1)
+			return true
+		}
+
+		var perm *PermanentError
+		if errors.As(err, &perm) {
+			q.logger.Warnf("queue: dropping batch for %s (permanent failure): %v", entry.batch.Endpoint, err)
+			atomic.AddInt64(&q.metrics.Dropped, 1)
+			return true
+		}
+
+		attempts++
+		if q.cfg.RetryMaxCount > 0 && attempts > q.cfg.RetryMaxCount {
+			q.logger.Warnf("queue: dropping batch for %s after %d attempts: %v", entry.batch.Endpoint, attempts, err)
+			atomic.AddInt64(&q.metrics.Dropped, 1)
+			return true
+		}
+
+		wait := q.backoff(attempts)
+		var retry *RetryableError
+		if errors.As(err, &retry) && retry.RetryAfter > 0 {
+			wait = retry.RetryAfter
+		}
+
+		atomic.AddInt64(&q.metrics.Retried, 1)
+		q.logger.Debugf("queue: retrying batch for %s in %s (attempt %d): %v", entry.batch.Endpoint, wait, attempts, err)
+
+		select {
+		case <-time.After(wait):
+		case <-q.stop:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// backoff computes an exponential backoff with full jitter for the given
+// 1-indexed attempt number.
+func (q *Queue) backoff(attempt int) time.Duration {
+	shift := attempt - 1
+	if shift > 20 {
+		shift = 20
+	}
+
+	capped := q.cfg.RetryDelay * time.Duration(uint64(1)<<uint(shift))
+	if capped <= 0 || capped > q.cfg.RetryMaxDelay {
+		capped = q.cfg.RetryMaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(capped) + 1))
+}
+
+// commitLoop advances and persists the cursor as completions arrive,
+// in order, and reclaims fully-consumed WAL segments.
+func (q *Queue) commitLoop() {
+	defer q.commitWG.Done()
+
+	for entry := range q.doneCh {
+		q.mu.Lock()
+		entry.done = true
+
+		idx := 0
+		for idx < len(q.pending) && q.pending[idx].done {
+			q.committed = q.pending[idx].next
+			idx++
+		}
+		committed := q.committed
+		drained := idx
+		q.pending = q.pending[idx:]
+		q.mu.Unlock()
+
+		if drained == 0 {
+			continue
+		}
+
+		atomic.AddInt64(&q.metrics.Depth, -int64(drained))
+
+		if err := saveCursor(q.cfg.Dir, committed); err != nil {
+			q.logger.Warnf("queue: failed to persist cursor: %v", err)
+			continue
+		}
+
+		if err := q.reclaimSegments(committed.Segment); err != nil {
+			q.logger.Warnf("queue: failed to reclaim WAL segments: %v", err)
+		}
+	}
+}
+
+// reclaimSegments removes WAL segments strictly older than the segment the
+// cursor now points into.
+func (q *Queue) reclaimSegments(upTo uint64) error {
+	seqs, err := q.wal.segmentSeqs()
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		if seq >= upTo {
+			break
+		}
+		if err := q.wal.removeSegment(seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
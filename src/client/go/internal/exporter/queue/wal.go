@@ -0,0 +1,269 @@
+package queue
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentExt        = ".wal"
+	recordHeaderBytes = 8 // 4-byte length + 4-byte CRC32 of the payload
+)
+
+// position identifies a byte offset inside a specific WAL segment.
+type position struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// persistedRecord is the JSON-encoded unit written to a WAL segment.
+type persistedRecord struct {
+	Endpoint        string `json:"endpoint"`
+	ContentType     string `json:"contentType"`
+	ContentEncoding string `json:"contentEncoding"`
+	Payload         []byte `json:"payload"`
+}
+
+// wal is a segmented, append-only write-ahead log. Records are appended to
+// the active segment and fsynced immediately; segments roll over once they
+// reach maxSegmentBytes.
+type wal struct {
+	dir             string
+	maxSegmentBytes int64
+
+	// mu guards append (and the rotate it may trigger) against concurrent
+	// callers: multiple OTel receivers can drive ConsumeLogs, and thus
+	// Enqueue, on separate goroutines.
+	mu         sync.Mutex
+	activeSeq  uint64
+	activeFile *os.File
+	activeSize int64
+}
+
+// openWAL opens (or creates) the WAL directory and starts appending to a
+// fresh segment after its newest one, or to segment 1 if none exist yet.
+// Never appending to a segment from a previous run keeps new records out of
+// a segment whose tail may hold a torn record, past which readers stop.
+func openWAL(dir string, maxSegmentBytes int64) (*wal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	w := &wal{dir: dir, maxSegmentBytes: maxSegmentBytes}
+
+	segments, err := w.segmentSeqs()
+	if err != nil {
+		return nil, err
+	}
+
+	seq := uint64(1)
+	if len(segments) > 0 {
+		seq = segments[len(segments)-1] + 1
+	}
+
+	if err := w.openActive(seq); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *wal) segmentPath(seq uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+// segmentSeqs returns the sequence numbers of every segment on disk, sorted
+// ascending.
+func (w *wal) segmentSeqs() ([]uint64, error) {
+	entries, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WAL directory: %w", err)
+	}
+
+	var seqs []uint64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), segmentExt) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), segmentExt)
+		seq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func (w *wal) openActive(seq uint64) error {
+	f, err := os.OpenFile(w.segmentPath(seq), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL segment %d: %w", seq, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat WAL segment %d: %w", seq, err)
+	}
+
+	w.activeSeq = seq
+	w.activeFile = f
+	w.activeSize = info.Size()
+	return nil
+}
+
+// append persists a batch as a new record in the active segment, rotating
+// to a new segment first if the record would push it past maxSegmentBytes.
+// The record is fsynced before append returns.
+func (w *wal) append(rec persistedRecord) (position, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return position{}, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	if w.activeSize > 0 && w.activeSize+int64(recordHeaderBytes+len(payload)) > w.maxSegmentBytes {
+		if err := w.rotate(); err != nil {
+			return position{}, err
+		}
+	}
+
+	pos := position{Segment: w.activeSeq, Offset: w.activeSize}
+
+	header := make([]byte, recordHeaderBytes)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+
+	if _, err := w.activeFile.Write(header); err != nil {
+		return position{}, fmt.Errorf("failed to append record header: %w", err)
+	}
+	if _, err := w.activeFile.Write(payload); err != nil {
+		return position{}, fmt.Errorf("failed to append record payload: %w", err)
+	}
+	if err := w.activeFile.Sync(); err != nil {
+		return position{}, fmt.Errorf("failed to fsync WAL segment: %w", err)
+	}
+
+	w.activeSize += int64(recordHeaderBytes + len(payload))
+	return pos, nil
+}
+
+func (w *wal) rotate() error {
+	if err := w.activeFile.Close(); err != nil {
+		return fmt.Errorf("failed to close WAL segment %d: %w", w.activeSeq, err)
+	}
+	return w.openActive(w.activeSeq + 1)
+}
+
+// removeSegment deletes a fully-consumed segment, refusing to touch the
+// active (still being written) segment.
+func (w *wal) removeSegment(seq uint64) error {
+	w.mu.Lock()
+	isActive := seq == w.activeSeq
+	w.mu.Unlock()
+	if isActive {
+		return nil
+	}
+	if err := os.Remove(w.segmentPath(seq)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove WAL segment %d: %w", seq, err)
+	}
+	return nil
+}
+
+func (w *wal) close() error {
+	return w.activeFile.Close()
+}
+
+// hasNewerSegment reports whether a segment after seq exists on disk, and
+// if so returns its sequence number.
+func (w *wal) hasNewerSegment(seq uint64) (uint64, bool, error) {
+	seqs, err := w.segmentSeqs()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(seqs) == 0 {
+		return 0, false, nil
+	}
+	max := seqs[len(seqs)-1]
+	if max > seq {
+		return seq + 1, true, nil
+	}
+	return 0, false, nil
+}
+
+// segmentReader reads records sequentially from a single WAL segment.
+type segmentReader struct {
+	seq uint64
+	f   *os.File
+}
+
+func openSegmentReader(dir string, seq uint64, offset int64) (*segmentReader, error) {
+	f, err := os.Open(filepath.Join(dir, fmt.Sprintf("%020d%s", seq, segmentExt)))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &segmentReader{seq: seq, f: f}, nil
+}
+
+// next reads the record starting at the reader's current position. It
+// returns io.EOF when the segment has no more complete records, and
+// io.ErrUnexpectedEOF when a partially-written (torn) record is found at
+// the tail, which the caller should treat as the end of valid data for
+// this segment rather than an error to surface.
+func (r *segmentReader) next() (persistedRecord, int64, error) {
+	header := make([]byte, recordHeaderBytes)
+	n, err := io.ReadFull(r.f, header)
+	if err == io.EOF {
+		return persistedRecord{}, 0, io.EOF
+	}
+	if err != nil || n != recordHeaderBytes {
+		return persistedRecord{}, 0, io.ErrUnexpectedEOF
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	wantCRC := binary.BigEndian.Uint32(header[4:8])
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r.f, payload); err != nil {
+		return persistedRecord{}, 0, io.ErrUnexpectedEOF
+	}
+
+	if crc32.ChecksumIEEE(payload) != wantCRC {
+		return persistedRecord{}, 0, io.ErrUnexpectedEOF
+	}
+
+	var rec persistedRecord
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return persistedRecord{}, 0, io.ErrUnexpectedEOF
+	}
+
+	offset, err := r.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return persistedRecord{}, 0, err
+	}
+
+	return rec, offset, nil
+}
+
+func (r *segmentReader) close() error {
+	return r.f.Close()
+}
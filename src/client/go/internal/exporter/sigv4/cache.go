@@ -0,0 +1,45 @@
+package sigv4
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// refreshBeforeExpiry is how long before a credential's ExpiresAt the
+// cache proactively refetches it, so an in-flight Sign call never races an
+// expiry.
+const refreshBeforeExpiry = 5 * time.Minute
+
+// CachingCredentialsProvider wraps another CredentialsProvider and reuses
+// its result until shortly before ExpiresAt, avoiding a network round trip
+// (e.g. to the EC2 instance metadata service) on every signed request.
+type CachingCredentialsProvider struct {
+	source CredentialsProvider
+
+	mu    sync.Mutex
+	cache Credentials
+}
+
+// NewCachingCredentialsProvider returns a CredentialsProvider that caches
+// source's result until it's within refreshBeforeExpiry of ExpiresAt.
+func NewCachingCredentialsProvider(source CredentialsProvider) *CachingCredentialsProvider {
+	return &CachingCredentialsProvider{source: source}
+}
+
+// Retrieve implements CredentialsProvider.
+func (p *CachingCredentialsProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cache.AccessKeyID != "" && (p.cache.ExpiresAt.IsZero() || time.Now().Before(p.cache.ExpiresAt.Add(-refreshBeforeExpiry))) {
+		return p.cache, nil
+	}
+
+	creds, err := p.source.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	p.cache = creds
+	return creds, nil
+}
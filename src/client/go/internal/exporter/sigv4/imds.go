@@ -0,0 +1,105 @@
+package sigv4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	imdsBaseURL     = "http://169.254.169.254/latest"
+	imdsTokenTTLSec = "21600"
+)
+
+// IMDSCredentialsProvider retrieves temporary credentials for the
+// instance's attached IAM role from the EC2 instance metadata service,
+// using the token-based IMDSv2 protocol.
+type IMDSCredentialsProvider struct {
+	Client *http.Client
+}
+
+// imdsCredentialsResponse mirrors the JSON body returned by
+// /latest/meta-data/iam/security-credentials/<role>.
+type imdsCredentialsResponse struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+	Expiration      string `json:"Expiration"`
+}
+
+// Retrieve implements CredentialsProvider by fetching a session token,
+// discovering the instance's IAM role, and fetching that role's
+// credentials.
+func (p *IMDSCredentialsProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	token, err := imdsGet(ctx, client, imdsBaseURL+"/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": imdsTokenTTLSec,
+	}, http.MethodPut)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("sigv4: failed to fetch IMDS token: %w", err)
+	}
+
+	roleHeaders := map[string]string{"X-aws-ec2-metadata-token": strings.TrimSpace(string(token))}
+
+	role, err := imdsGet(ctx, client, imdsBaseURL+"/meta-data/iam/security-credentials/", roleHeaders, http.MethodGet)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("sigv4: failed to discover IAM role: %w", err)
+	}
+
+	body, err := imdsGet(ctx, client, imdsBaseURL+"/meta-data/iam/security-credentials/"+strings.TrimSpace(string(role)), roleHeaders, http.MethodGet)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("sigv4: failed to fetch IMDS credentials: %w", err)
+	}
+
+	var creds imdsCredentialsResponse
+	if err := json.Unmarshal(body, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("sigv4: failed to parse IMDS credentials: %w", err)
+	}
+
+	result := Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    strings.TrimSpace(creds.Token),
+	}
+	if creds.Expiration != "" {
+		if expiresAt, err := time.Parse(time.RFC3339, creds.Expiration); err == nil {
+			result.ExpiresAt = expiresAt
+		}
+	}
+	return result, nil
+}
+
+// imdsGet issues method against url with the given headers and returns the
+// response body.
+func imdsGet(ctx context.Context, client *http.Client, url string, headers map[string]string, method string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	return body, nil
+}
@@ -0,0 +1,88 @@
+package exporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lognarrator/client/internal/config"
+	"github.com/lognarrator/client/internal/exporter/sigv4"
+)
+
+// RequestSigner signs an outbound export request, e.g. with AWS SigV4, so
+// it can be accepted by endpoints that require signed requests. body is
+// the already-finalized request payload (post encryption/compression),
+// since most signing schemes authenticate a hash of the exact bytes sent.
+type RequestSigner interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// buildTransport constructs the *http.Transport for cfg: client
+// certificate (mTLS) and CA bundle if configured, InsecureSkipVerify per
+// TLSVerify, and a proxy resolved from cfg.Proxy or the environment.
+func buildTransport(cfg config.ExporterConfig) (*http.Transport, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.TLSVerify != nil && !*cfg.TLSVerify,
+	}
+
+	if cfg.TLSCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertPath, cfg.TLSKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.TLSCAPath != "" {
+		caPEM, err := ioutil.ReadFile(cfg.TLSCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA bundle at %s", cfg.TLSCAPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	proxyFunc := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse exporter proxy URL: %w", err)
+		}
+		proxyFunc = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Transport{
+		TLSClientConfig: tlsConfig,
+		Proxy:           proxyFunc,
+	}, nil
+}
+
+// buildSigner constructs the RequestSigner selected by cfg.Auth, or nil if
+// request signing isn't configured.
+func buildSigner(cfg config.AuthConfig) (RequestSigner, error) {
+	switch {
+	case cfg.Type == "" || strings.EqualFold(cfg.Type, "none"):
+		return nil, nil
+	case strings.EqualFold(cfg.Type, "sigv4"):
+		var creds sigv4.CredentialsProvider
+		if cfg.UseIMDS {
+			creds = sigv4.NewCachingCredentialsProvider(&sigv4.IMDSCredentialsProvider{})
+		} else {
+			creds = sigv4.StaticCredentialsProvider{Credentials: sigv4.Credentials{
+				AccessKeyID:     cfg.AccessKeyID,
+				SecretAccessKey: cfg.SecretAccessKey,
+				SessionToken:    cfg.SessionToken,
+			}}
+		}
+		return sigv4.NewSigner(cfg.Region, cfg.Service, creds), nil
+	default:
+		return nil, fmt.Errorf("unsupported exporter auth type: %s", cfg.Type)
+	}
+}